@@ -0,0 +1,39 @@
+// Command storktest-record drives storktest.Record against a live Stork
+// endpoint, capturing real frames into a fixture file that can be
+// replayed offline via storktest.LoadFixture/NewReplayServer. It is the
+// CLI that storktest.Record's doc comment points to.
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/InjectiveLabs/injective-price-oracle/pkg/storktest"
+)
+
+func main() {
+	url := flag.String("url", "", "Stork WebSocket endpoint to dial (required)")
+	header := flag.String("header", "", "Basic auth header value to send, if any")
+	message := flag.String("message", "", "subscribe message to send after connecting (required)")
+	frames := flag.Int("frames", 10, "number of frames to capture before exiting")
+	out := flag.String("out", "", "path to write the captured fixture to (required)")
+	flag.Parse()
+
+	if *url == "" || *message == "" || *out == "" {
+		flag.Usage()
+		log.Fatal("-url, -message, and -out are all required")
+	}
+
+	err := storktest.Record(storktest.RecordOptions{
+		Url:         *url,
+		Header:      *header,
+		Message:     *message,
+		FrameCount:  *frames,
+		FixturePath: *out,
+	})
+	if err != nil {
+		log.Fatalf("record failed: %v", err)
+	}
+
+	log.Printf("wrote %d frames to %s", *frames, *out)
+}
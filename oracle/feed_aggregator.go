@@ -0,0 +1,482 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/InjectiveLabs/metrics"
+	oracletypes "github.com/InjectiveLabs/sdk-go/chain/oracle/types"
+	toml "github.com/pelletier/go-toml/v2"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	log "github.com/xlab/suplog"
+)
+
+// FeedProviderAggregator identifies a PricePuller whose value is
+// composed from several underlying PriceProvider sources rather than
+// pulled from a single upstream.
+const FeedProviderAggregator FeedProvider = "aggregator"
+
+var _ PricePuller = &AggregatingPuller{}
+
+// AggregatorSourceConfig configures one PriceProvider behind an
+// AggregatingPuller. Type selects which fields apply: "stork" uses the
+// Stork* fields, "http" uses Url/Header/JSONPath, "cosmosGrpc" uses the
+// GRPC* fields.
+type AggregatorSourceConfig struct {
+	Type         string `toml:"type"`
+	PullInterval string `toml:"pullInterval"`
+	Weight       string `toml:"weight"`
+
+	StorkUrl     string `toml:"storkUrl"`
+	StorkHeader  string `toml:"storkHeader"`
+	StorkMessage string `toml:"storkMessage"`
+	StorkAssetId string `toml:"storkAssetId"`
+	// StorkMaxCacheAge is how long a cached stork price may go without a
+	// fresh update before PullPrice treats it as stale, e.g. "2m".
+	// Defaults to defaultStorkStaleAfter.
+	StorkMaxCacheAge string `toml:"storkMaxCacheAge"`
+
+	Url      string `toml:"url"`
+	Header   string `toml:"header"`
+	JSONPath string `toml:"jsonPath"`
+
+	GRPCEndpoint string `toml:"grpcEndpoint"`
+	GRPCSymbol   string `toml:"grpcSymbol"`
+}
+
+// AggregatorFeedConfig is the [aggregator] TOML block: a ticker fed by
+// one or more [[aggregator.source]] providers, reduced to a single value
+// per round.
+type AggregatorFeedConfig struct {
+	ProviderName string `toml:"provider"`
+	Ticker       string `toml:"ticker"`
+	PullInterval string `toml:"pullInterval"`
+	OracleType   string `toml:"oracleType"`
+
+	// MinQuorum is the minimum number of sources that must contribute a
+	// fresh, non-outlier price agreeing with the aggregate for a round
+	// to be accepted.
+	MinQuorum int `toml:"minQuorum"`
+	// MaxDeviation bounds how far (as a fraction, e.g. "0.02" for 2%) a
+	// source's price may sit from the aggregate and still count toward
+	// MinQuorum. Zero (the default) skips this agreement check, so
+	// MinQuorum then only counts fresh, non-outlier sources.
+	MaxDeviation string `toml:"maxDeviation"`
+	// MadThreshold is K in "drop any source beyond K median absolute
+	// deviations from the median"; zero disables outlier filtering.
+	MadThreshold string `toml:"madThreshold"`
+
+	Source []AggregatorSourceConfig `toml:"source"`
+}
+
+// ParseAggregatorFeedConfig parses a TOML document containing a single
+// top-level [aggregator] table.
+func ParseAggregatorFeedConfig(body []byte) (*AggregatorFeedConfig, error) {
+	var wrapper struct {
+		Aggregator AggregatorFeedConfig `toml:"aggregator"`
+	}
+	if err := toml.Unmarshal(body, &wrapper); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal TOML config")
+	}
+
+	return &wrapper.Aggregator, nil
+}
+
+type cachedSourcePrice struct {
+	price    decimal.Decimal
+	pulledAt time.Time
+}
+
+// AggregatingPuller is a PricePuller that composes N PriceProvider
+// sources per ticker. Each source is pulled on its own interval by a
+// dedicated goroutine into a per-source TTL cache; PullPrice reduces
+// whatever is currently fresh by filtering outliers beyond MadThreshold
+// median absolute deviations, taking the weight-weighted median of what
+// remains, and requiring at least MinQuorum sources to agree with that
+// median within MaxDeviation.
+type AggregatingPuller struct {
+	ticker       string
+	providerName string
+	interval     time.Duration
+	oracleType   oracletypes.OracleType
+
+	sources    []PriceProvider
+	sourceTTLs map[string]time.Duration
+
+	minQuorum    int
+	maxDeviation decimal.Decimal
+	madThreshold decimal.Decimal
+
+	logger  log.Logger
+	svcTags metrics.Tags
+
+	cacheMu        sync.RWMutex
+	perSourceCache map[string]cachedSourcePrice
+
+	cancel context.CancelFunc
+}
+
+// NewAggregatingPuller builds an AggregatingPuller from cfg and starts
+// one background puller goroutine per configured source.
+func NewAggregatingPuller(cfg *AggregatorFeedConfig) (PricePuller, error) {
+	if len(cfg.Source) == 0 {
+		return nil, errors.New("aggregator requires at least one [[aggregator.source]]")
+	}
+
+	pullInterval := 1 * time.Minute
+	if cfg.PullInterval != "" {
+		interval, err := time.ParseDuration(cfg.PullInterval)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse pull interval: %s", cfg.PullInterval)
+		}
+		pullInterval = interval
+	}
+
+	var oracleType oracletypes.OracleType
+	if cfg.OracleType == "" {
+		oracleType = oracletypes.OracleType_PriceFeed
+	} else {
+		tmpType, exist := oracletypes.OracleType_value[cfg.OracleType]
+		if !exist {
+			return nil, fmt.Errorf("oracle type does not exist: %s", cfg.OracleType)
+		}
+		oracleType = oracletypes.OracleType(tmpType)
+	}
+
+	if oracleType == oracletypes.OracleType_Stork {
+		return nil, errors.New("aggregator does not support oracleType Stork; its sources are already combined into a plain price, use PriceFeed or another non-Stork oracle type")
+	}
+
+	minQuorum := cfg.MinQuorum
+	if minQuorum <= 0 {
+		minQuorum = 1
+	}
+
+	maxDeviation := decimal.Zero
+	if cfg.MaxDeviation != "" {
+		d, err := decimal.NewFromString(cfg.MaxDeviation)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse maxDeviation: %s", cfg.MaxDeviation)
+		}
+		maxDeviation = d
+	}
+
+	madThreshold := decimal.Zero
+	if cfg.MadThreshold != "" {
+		d, err := decimal.NewFromString(cfg.MadThreshold)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse madThreshold: %s", cfg.MadThreshold)
+		}
+		madThreshold = d
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p := &AggregatingPuller{
+		ticker:       cfg.Ticker,
+		providerName: cfg.ProviderName,
+		interval:     pullInterval,
+		oracleType:   oracleType,
+
+		sourceTTLs: make(map[string]time.Duration, len(cfg.Source)),
+
+		minQuorum:    minQuorum,
+		maxDeviation: maxDeviation,
+		madThreshold: madThreshold,
+
+		logger: log.WithFields(log.Fields{
+			"svc":      "oracle",
+			"dynamic":  true,
+			"provider": cfg.ProviderName,
+		}),
+		svcTags: metrics.Tags{
+			"provider": cfg.ProviderName,
+		},
+
+		perSourceCache: make(map[string]cachedSourcePrice, len(cfg.Source)),
+		cancel:         cancel,
+	}
+
+	for i, srcCfg := range cfg.Source {
+		src, srcInterval, err := newPriceSource(cfg.Ticker, i, srcCfg)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		p.sources = append(p.sources, src)
+		p.sourceTTLs[src.Name()] = 2 * srcInterval
+
+		go p.runSource(ctx, src, srcInterval)
+	}
+
+	return p, nil
+}
+
+// newPriceSource builds the PriceProvider described by cfg.
+func newPriceSource(ticker string, index int, cfg AggregatorSourceConfig) (PriceProvider, time.Duration, error) {
+	interval := 1 * time.Minute
+	if cfg.PullInterval != "" {
+		d, err := time.ParseDuration(cfg.PullInterval)
+		if err != nil {
+			return nil, 0, errors.Wrapf(err, "failed to parse source pullInterval: %s", cfg.PullInterval)
+		}
+		interval = d
+	}
+
+	weight := decimal.NewFromInt(1)
+	if cfg.Weight != "" {
+		w, err := decimal.NewFromString(cfg.Weight)
+		if err != nil {
+			return nil, 0, errors.Wrapf(err, "failed to parse source weight: %s", cfg.Weight)
+		}
+		weight = w
+	}
+
+	name := fmt.Sprintf("%s-%s-%d", ticker, cfg.Type, index)
+
+	switch cfg.Type {
+	case "stork":
+		client := getOrCreateStorkWSClient(cfg.StorkUrl, cfg.StorkHeader)
+
+		verifier, err := newStorkVerifier(&StorkFeedConfig{})
+		if err != nil {
+			return nil, 0, err
+		}
+		client.mergeVerifier(verifier)
+
+		staleAfter := defaultStorkStaleAfter
+		if cfg.StorkMaxCacheAge != "" {
+			d, err := time.ParseDuration(cfg.StorkMaxCacheAge)
+			if err != nil {
+				return nil, 0, errors.Wrapf(err, "failed to parse storkMaxCacheAge: %s (expected format: 2m)", cfg.StorkMaxCacheAge)
+			}
+			staleAfter = d
+		}
+
+		return newStorkSourceProvider(name, cfg.StorkAssetId, cfg.StorkMessage, weight, client, staleAfter), interval, nil
+
+	case "http":
+		return NewHTTPJSONPathProvider(name, cfg.Url, cfg.Header, cfg.JSONPath, weight), interval, nil
+
+	case "cosmosGrpc":
+		provider, err := NewCosmosGRPCProvider(name, cfg.GRPCEndpoint, ticker, cfg.GRPCSymbol, weight)
+		if err != nil {
+			return nil, 0, err
+		}
+		return provider, interval, nil
+
+	default:
+		return nil, 0, errors.Errorf("unknown aggregator source type: %s", cfg.Type)
+	}
+}
+
+func (p *AggregatingPuller) runSource(ctx context.Context, src PriceProvider, interval time.Duration) {
+	p.pullSourceOnce(ctx, src)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.pullSourceOnce(ctx, src)
+		}
+	}
+}
+
+func (p *AggregatingPuller) pullSourceOnce(ctx context.Context, src PriceProvider) {
+	price, err := src.PullPrice(ctx)
+	if err != nil {
+		p.logger.WithField("source", src.Name()).WithError(err).Warningln("failed to pull price from aggregator source")
+		return
+	}
+
+	p.cacheMu.Lock()
+	p.perSourceCache[src.Name()] = cachedSourcePrice{price: price, pulledAt: time.Now()}
+	p.cacheMu.Unlock()
+}
+
+func (p *AggregatingPuller) Interval() time.Duration {
+	return p.interval
+}
+
+func (p *AggregatingPuller) Symbol() string {
+	return p.ticker
+}
+
+func (p *AggregatingPuller) Provider() FeedProvider {
+	return FeedProviderAggregator
+}
+
+func (p *AggregatingPuller) ProviderName() string {
+	return p.providerName
+}
+
+func (p *AggregatingPuller) OracleType() oracletypes.OracleType {
+	return p.oracleType
+}
+
+// PullPrice reduces whatever sources currently hold a fresh value to a
+// single weighted-median price, after dropping MAD outliers, and errors
+// if fewer than MinQuorum sources survive both filters.
+func (p *AggregatingPuller) PullPrice(ctx context.Context) (decimal.Decimal, error) {
+	metrics.ReportFuncCall(p.svcTags)
+	doneFn := metrics.ReportFuncTiming(p.svcTags)
+	defer doneFn()
+
+	prices, weights := p.freshSourcePrices()
+	if len(prices) < p.minQuorum {
+		metrics.ReportFuncError(p.svcTags)
+		return decimal.Zero, errors.Errorf("only %d/%d required sources have a fresh price for %s", len(prices), p.minQuorum, p.ticker)
+	}
+
+	prices, weights = filterOutliers(prices, weights, p.madThreshold)
+	if len(prices) < p.minQuorum {
+		metrics.ReportFuncError(p.svcTags)
+		return decimal.Zero, errors.Errorf("only %d/%d required sources agree within %s MADs for %s", len(prices), p.minQuorum, p.madThreshold, p.ticker)
+	}
+
+	aggregate := weightedMedian(prices, weights)
+	if aggregate.IsZero() {
+		metrics.ReportFuncError(p.svcTags)
+		return decimal.Zero, errors.Errorf("aggregated price for %s is zero, rejecting round", p.ticker)
+	}
+
+	if !p.maxDeviation.IsZero() {
+		agreeing := 0
+		for _, price := range prices {
+			if price.Sub(aggregate).Abs().Div(aggregate).LessThanOrEqual(p.maxDeviation) {
+				agreeing++
+			}
+		}
+		if agreeing < p.minQuorum {
+			metrics.ReportFuncError(p.svcTags)
+			return decimal.Zero, errors.Errorf(
+				"only %d/%d required sources agree within %s of the aggregate for %s", agreeing, p.minQuorum, p.maxDeviation, p.ticker,
+			)
+		}
+	}
+
+	return aggregate, nil
+}
+
+func (p *AggregatingPuller) freshSourcePrices() (prices, weights []decimal.Decimal) {
+	p.cacheMu.RLock()
+	defer p.cacheMu.RUnlock()
+
+	for _, src := range p.sources {
+		cached, ok := p.perSourceCache[src.Name()]
+		if !ok || time.Since(cached.pulledAt) > p.sourceTTLs[src.Name()] {
+			continue
+		}
+
+		prices = append(prices, cached.price)
+		weights = append(weights, src.Weight())
+	}
+
+	return prices, weights
+}
+
+// PullAssetPair is not meaningful for an aggregator: its sources emit a
+// plain price, not a Stork-style signed asset pair, so submission goes
+// through PullPrice instead (mirroring storkPriceFeed's unused PullPrice
+// stub on the other side of that same split). It errors rather than
+// returning an empty success so a feed misconfigured with
+// oracleType = "Stork" fails loudly instead of submitting an empty
+// asset pair.
+func (p *AggregatingPuller) PullAssetPair(ctx context.Context) (oracletypes.AssetPair, error) {
+	return oracletypes.AssetPair{}, errors.New("aggregator sources emit a plain price, not a Stork-style signed asset pair; use PullPrice instead")
+}
+
+// medianDecimal returns the median of values, without mutating values.
+func medianDecimal(values []decimal.Decimal) decimal.Decimal {
+	if len(values) == 0 {
+		return decimal.Zero
+	}
+
+	sorted := make([]decimal.Decimal, len(values))
+	copy(sorted, values)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].LessThan(sorted[j]) })
+
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+
+	return sorted[n/2-1].Add(sorted[n/2]).Div(decimal.NewFromInt(2))
+}
+
+// filterOutliers drops any (price, weight) pair whose absolute deviation
+// from the median exceeds k times the median absolute deviation (MAD).
+// It is a no-op for fewer than 3 prices or a zero k, since MAD isn't a
+// meaningful filter below that.
+func filterOutliers(prices, weights []decimal.Decimal, k decimal.Decimal) ([]decimal.Decimal, []decimal.Decimal) {
+	if len(prices) < 3 || k.IsZero() {
+		return prices, weights
+	}
+
+	med := medianDecimal(prices)
+
+	deviations := make([]decimal.Decimal, len(prices))
+	for i, price := range prices {
+		deviations[i] = price.Sub(med).Abs()
+	}
+
+	mad := medianDecimal(deviations)
+	if mad.IsZero() {
+		return prices, weights
+	}
+
+	threshold := mad.Mul(k)
+
+	keptPrices := make([]decimal.Decimal, 0, len(prices))
+	keptWeights := make([]decimal.Decimal, 0, len(weights))
+	for i, price := range prices {
+		if price.Sub(med).Abs().LessThanOrEqual(threshold) {
+			keptPrices = append(keptPrices, price)
+			keptWeights = append(keptWeights, weights[i])
+		}
+	}
+
+	return keptPrices, keptWeights
+}
+
+// weightedMedian returns the price at which cumulative weight first
+// reaches half of the total weight.
+func weightedMedian(prices, weights []decimal.Decimal) decimal.Decimal {
+	if len(prices) == 0 {
+		return decimal.Zero
+	}
+
+	type weightedPrice struct {
+		price  decimal.Decimal
+		weight decimal.Decimal
+	}
+
+	pairs := make([]weightedPrice, len(prices))
+	totalWeight := decimal.Zero
+	for i, price := range prices {
+		pairs[i] = weightedPrice{price: price, weight: weights[i]}
+		totalWeight = totalWeight.Add(weights[i])
+	}
+
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].price.LessThan(pairs[j].price) })
+
+	half := totalWeight.Div(decimal.NewFromInt(2))
+	cumulative := decimal.Zero
+	for _, pair := range pairs {
+		cumulative = cumulative.Add(pair.weight)
+		if cumulative.GreaterThanOrEqual(half) {
+			return pair.price
+		}
+	}
+
+	return pairs[len(pairs)-1].price
+}
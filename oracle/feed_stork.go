@@ -2,11 +2,7 @@ package oracle
 
 import (
 	"context"
-	"encoding/base64"
-	"encoding/json"
 	"fmt"
-	"net/http"
-	"net/url"
 	"strings"
 	"time"
 
@@ -14,23 +10,46 @@ import (
 	"github.com/InjectiveLabs/metrics"
 	oracletypes "github.com/InjectiveLabs/sdk-go/chain/oracle/types"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/gorilla/websocket"
 	toml "github.com/pelletier/go-toml/v2"
 	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
 	log "github.com/xlab/suplog"
 )
 
+// defaultStorkStaleAfter is how long a cached price may go without a
+// fresh update from the shared storkWSClient before PullAssetPair treats
+// it as unusable and reports an error instead of submitting a stale
+// value, when StorkFeedConfig.MaxCacheAge is left unset.
+const defaultStorkStaleAfter = 2 * time.Minute
+
 var _ PricePuller = &storkPriceFeed{}
 
 type StorkFeedConfig struct {
 	ProviderName string `toml:"provider"`
 	Ticker       string `toml:"ticker"`
+	AssetId      string `toml:"assetId"`
 	PullInterval string `toml:"pullInterval"`
 	Url          string `toml:"url"`
 	Header       string `toml:"header"`
 	Message      string `toml:"message"`
 	OracleType   string `toml:"oracleType"`
+
+	// AllowedPublishers is the allow-list of publisher keys (hex
+	// addresses) a signed price's recovered signer must belong to. Left
+	// empty, any correctly-signed publisher is accepted.
+	AllowedPublishers []string `toml:"allowedPublishers"`
+	// MaxPriceAge rejects any signed price older than this, e.g. "2m".
+	MaxPriceAge string `toml:"maxPriceAge"`
+	// MaxFutureSkew rejects any signed price timestamped further than
+	// this into the future, e.g. "5s".
+	MaxFutureSkew string `toml:"maxFutureSkew"`
+	// Quorum is the minimum number of valid signed prices required
+	// before PullAssetPair will return a result for this round.
+	Quorum int `toml:"quorum"`
+	// MaxCacheAge is how long a cached price may go without a fresh
+	// update before PullAssetPair treats it as stale, e.g. "2m". Defaults
+	// to defaultStorkStaleAfter.
+	MaxCacheAge string `toml:"maxCacheAge"`
 }
 
 type StorkConfig struct {
@@ -38,11 +57,12 @@ type StorkConfig struct {
 
 type storkPriceFeed struct {
 	ticker       string
+	assetId      string
 	providerName string
 	interval     time.Duration
-	url          string
-	header       string
-	message      string
+
+	client     *storkWSClient
+	staleAfter time.Duration
 
 	logger  log.Logger
 	svcTags metrics.Tags
@@ -90,13 +110,37 @@ func NewStorkPriceFeed(cfg *StorkFeedConfig) (PricePuller, error) {
 		oracleType = oracletypes.OracleType(tmpType)
 	}
 
+	assetId := cfg.AssetId
+	if assetId == "" {
+		assetId = cfg.Ticker
+	}
+
+	staleAfter := defaultStorkStaleAfter
+	if len(cfg.MaxCacheAge) > 0 {
+		d, err := time.ParseDuration(cfg.MaxCacheAge)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse maxCacheAge: %s (expected format: 2m)", cfg.MaxCacheAge)
+		}
+
+		staleAfter = d
+	}
+
+	verifier, err := newStorkVerifier(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	client := getOrCreateStorkWSClient(cfg.Url, cfg.Header)
+	client.mergeVerifier(verifier)
+	client.Subscribe(assetId, []byte(cfg.Message))
+
 	feed := &storkPriceFeed{
 		ticker:       cfg.Ticker,
+		assetId:      assetId,
 		providerName: cfg.ProviderName,
 		interval:     pullInterval,
-		url:          cfg.Url,
-		header:       cfg.Header,
-		message:      cfg.Message,
+		client:       client,
+		staleAfter:   staleAfter,
 		oracleType:   oracleType,
 
 		logger: log.WithFields(log.Fields{
@@ -133,72 +177,28 @@ func (f *storkPriceFeed) OracleType() oracletypes.OracleType {
 	return oracletypes.OracleType_Stork
 }
 
-// PullAssetPair pulls asset pair for an asset id
+// PullAssetPair returns the most recently cached asset pair for this
+// feed's ticker. It never touches the network itself: the shared
+// storkWSClient keeps the cache warm in the background, so this is a
+// non-blocking read that fails if no value has arrived yet or the
+// cached value is older than f.staleAfter.
 func (f *storkPriceFeed) PullAssetPair(ctx context.Context) (assetPairs oracletypes.AssetPair, err error) {
 	metrics.ReportFuncCall(f.svcTags)
 	doneFn := metrics.ReportFuncTiming(f.svcTags)
 	defer doneFn()
 
-	// Parse the URL
-	u, err := url.Parse(f.url)
-	if err != nil {
-		log.Fatal("Error parsing URL:", err)
-		return oracletypes.AssetPair{}, nil
+	pair, updatedAt, ok := f.client.Get(f.assetId)
+	if !ok {
+		metrics.ReportFuncError(f.svcTags)
+		return oracletypes.AssetPair{}, errors.Wrapf(errStorkPriceNotCached, "asset %s", f.assetId)
 	}
-	header := http.Header{}
-	header.Add("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(f.header)))
-
-	dialer := websocket.DefaultDialer
-	dialer.EnableCompression = true
 
-	// Connect to the WebSocket server
-	conn, resp, err := dialer.Dial(u.String(), header)
-	if err != nil {
-		if resp != nil {
-			log.Printf("Handshake failed with status: %d\n", resp.StatusCode)
-			for k, v := range resp.Header {
-				log.Printf("%s: %v\n", k, v)
-			}
-		}
-		log.Fatal("Error connecting to WebSocket:", err)
-		return oracletypes.AssetPair{}, nil
-	}
-	defer conn.Close()
-
-	log.Println("Connected to WebSocket server:", resp.Status)
-
-	err = conn.WriteMessage(websocket.TextMessage, []byte(f.message))
-	if err != nil {
-		log.Fatal("Error writing message:", err)
-		return oracletypes.AssetPair{}, nil
-	}
-
-	var msgNeed []byte
-	count := 0
-	for count < 2 {
-		_, message, err := conn.ReadMessage()
-		if err != nil {
-			log.Println("Error reading message:", err)
-			return oracletypes.AssetPair{}, nil
-
-		}
-		msgNeed = message
-		count += 1
-	}
-
-	log.Println("Interrupt received, closing connection")
-
-	var msgResp messageResponse
-	if err = json.Unmarshal(msgNeed, &msgResp); err != nil {
-		return oracletypes.AssetPair{}, nil
+	if age := time.Since(updatedAt); age > f.staleAfter {
+		metrics.ReportFuncError(f.svcTags)
+		return oracletypes.AssetPair{}, errors.Errorf("stale stork price for asset %s (age %s)", f.assetId, age)
 	}
-	assetIds := make([]string, 0)
-	for key := range msgResp.Data {
-		assetIds = append(assetIds, key)
-	}
-	assetPairs = ConvertDataToAssetPair(msgResp.Data[assetIds[0]], assetIds[0])
 
-	return assetPairs, nil
+	return pair, nil
 }
 
 func (f *storkPriceFeed) PullPrice(ctx context.Context) (
@@ -277,4 +277,4 @@ type Signature struct {
 	R string `json:"r"`
 	S string `json:"s"`
 	V string `json:"v"`
-}
\ No newline at end of file
+}
@@ -0,0 +1,181 @@
+package oracle
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	oracletypes "github.com/InjectiveLabs/sdk-go/chain/oracle/types"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/tidwall/gjson"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// PriceProvider is one upstream source of a single ticker's price.
+// AggregatingPuller composes several of these per ticker and reduces
+// their outputs to one aggregated value each round.
+type PriceProvider interface {
+	Name() string
+	Weight() decimal.Decimal
+	PullPrice(ctx context.Context) (decimal.Decimal, error)
+}
+
+// storkSourceProvider adapts a ticker on a shared storkWSClient to the
+// PriceProvider interface by taking the median of its cached, already
+// signature-verified prices.
+type storkSourceProvider struct {
+	name       string
+	weight     decimal.Decimal
+	assetId    string
+	client     *storkWSClient
+	staleAfter time.Duration
+}
+
+func newStorkSourceProvider(name, assetId, message string, weight decimal.Decimal, client *storkWSClient, staleAfter time.Duration) *storkSourceProvider {
+	client.Subscribe(assetId, []byte(message))
+
+	return &storkSourceProvider{
+		name:       name,
+		weight:     weight,
+		assetId:    assetId,
+		client:     client,
+		staleAfter: staleAfter,
+	}
+}
+
+func (p *storkSourceProvider) Name() string            { return p.name }
+func (p *storkSourceProvider) Weight() decimal.Decimal { return p.weight }
+
+func (p *storkSourceProvider) PullPrice(ctx context.Context) (decimal.Decimal, error) {
+	pair, updatedAt, ok := p.client.Get(p.assetId)
+	if !ok {
+		return decimal.Zero, errors.Errorf("no stork price cached yet for asset %s", p.assetId)
+	}
+	if time.Since(updatedAt) > p.staleAfter {
+		return decimal.Zero, errors.Errorf("stork price for asset %s is stale", p.assetId)
+	}
+
+	return medianSignedPrice(pair)
+}
+
+func medianSignedPrice(pair oracletypes.AssetPair) (decimal.Decimal, error) {
+	if len(pair.SignedPrices) == 0 {
+		return decimal.Zero, errors.New("asset pair has no signed prices")
+	}
+
+	prices := make([]decimal.Decimal, 0, len(pair.SignedPrices))
+	for _, sp := range pair.SignedPrices {
+		prices = append(prices, decimal.NewFromBigInt(sp.Price.BigInt(), -18))
+	}
+
+	return medianDecimal(prices), nil
+}
+
+// HTTPJSONPathProvider pulls a price from any REST endpoint that returns
+// JSON, extracting the value at jsonPath (gjson dot-path syntax, e.g.
+// "data.price").
+type HTTPJSONPathProvider struct {
+	name     string
+	weight   decimal.Decimal
+	url      string
+	header   string
+	jsonPath string
+
+	httpClient *http.Client
+}
+
+func NewHTTPJSONPathProvider(name, url, header, jsonPath string, weight decimal.Decimal) *HTTPJSONPathProvider {
+	return &HTTPJSONPathProvider{
+		name:       name,
+		weight:     weight,
+		url:        url,
+		header:     header,
+		jsonPath:   jsonPath,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *HTTPJSONPathProvider) Name() string            { return p.name }
+func (p *HTTPJSONPathProvider) Weight() decimal.Decimal { return p.weight }
+
+func (p *HTTPJSONPathProvider) PullPrice(ctx context.Context) (decimal.Decimal, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return decimal.Zero, errors.Wrap(err, "failed to build request")
+	}
+	if p.header != "" {
+		req.Header.Set("Authorization", p.header)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return decimal.Zero, errors.Wrap(err, "failed to pull price")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return decimal.Zero, errors.Wrap(err, "failed to read response body")
+	}
+
+	result := gjson.GetBytes(body, p.jsonPath)
+	if !result.Exists() {
+		return decimal.Zero, errors.Errorf("jsonPath %q matched nothing in response", p.jsonPath)
+	}
+
+	price, err := decimal.NewFromString(result.String())
+	if err != nil {
+		return decimal.Zero, errors.Wrapf(err, "failed to parse price %q", result.String())
+	}
+
+	return price, nil
+}
+
+// CosmosGRPCProvider pulls a price from another chain's oracle module
+// over gRPC, reusing the Injective oracle query types since the source
+// chain is expected to expose a compatible price-feed query.
+type CosmosGRPCProvider struct {
+	name   string
+	weight decimal.Decimal
+
+	base  string
+	quote string
+
+	client oracletypes.QueryClient
+}
+
+func NewCosmosGRPCProvider(name, endpoint, base, quote string, weight decimal.Decimal) (*CosmosGRPCProvider, error) {
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to dial cosmos oracle grpc endpoint %s", endpoint)
+	}
+
+	return &CosmosGRPCProvider{
+		name:   name,
+		weight: weight,
+		base:   base,
+		quote:  quote,
+		client: oracletypes.NewQueryClient(conn),
+	}, nil
+}
+
+func (p *CosmosGRPCProvider) Name() string            { return p.name }
+func (p *CosmosGRPCProvider) Weight() decimal.Decimal { return p.weight }
+
+func (p *CosmosGRPCProvider) PullPrice(ctx context.Context) (decimal.Decimal, error) {
+	resp, err := p.client.PriceFeedPriceState(ctx, &oracletypes.QueryPriceFeedPriceStateRequest{
+		Base:  p.base,
+		Quote: p.quote,
+	})
+	if err != nil {
+		return decimal.Zero, errors.Wrap(err, "failed to query remote oracle price feed state")
+	}
+	if resp.PriceState == nil {
+		return decimal.Zero, errors.New("remote oracle returned empty price state")
+	}
+
+	return decimal.NewFromBigInt(resp.PriceState.Price.BigInt(), -18), nil
+}
@@ -0,0 +1,188 @@
+package oracle
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	"github.com/InjectiveLabs/injective-price-oracle/pkg/storktest"
+	oracletypes "github.com/InjectiveLabs/sdk-go/chain/oracle/types"
+)
+
+// TestStorkReplayVerifiesSignedPrice drives a fixture built from a real
+// (throwaway) secp256k1 signature through an httptest WebSocket replay
+// server, the real storkWSClient, and the real verification pipeline,
+// then checks the cached result against a golden file.
+//
+// The fixture's raw frame is generated at test time rather than
+// committed as static testdata, because its signature bytes depend on
+// crypto.Sign and this sandbox has no cached go-ethereum/crypto module
+// to compute them up front. Run this test once with
+// STORKTEST_UPDATE_GOLDEN=1 in an environment with that module
+// available to seed testdata/stork/valid_single_publisher.golden.json;
+// afterwards it's a normal byte-exact regression check. The timestamp is
+// fixed (and the verifier's maxPriceAge widened to accept it) so the
+// signed bytes, and therefore the golden file, are the same every run.
+func TestStorkReplayVerifiesSignedPrice(t *testing.T) {
+	const assetId = "BTCUSD"
+	price := math.LegacyMustNewDecFromStr("65000.50")
+	timestamp := uint64(1700000000000000000)
+
+	signed := signTestPrice(t, assetId, price, timestamp)
+
+	msg := messageResponse{
+		Type:    "oracle_prices",
+		TraceID: "replay-valid-single-publisher",
+		Data: map[string]Data{
+			assetId: {
+				Timestamp:     time.Now().Unix(),
+				AssetID:       assetId,
+				SignatureType: "evm",
+				Price:         price.String(),
+				SignedPrices:  []SignedPrice{signed},
+			},
+		},
+	}
+
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("failed to marshal fixture frame: %v", err)
+	}
+
+	server := storktest.NewReplayServer([]storktest.Frame{{Version: 1, Raw: raw}})
+	defer server.Close()
+
+	client := newStorkWSClient(storktest.WSURL(server), "")
+	defer client.cancel()
+
+	client.mergeVerifier(testVerifier(t, &StorkFeedConfig{
+		AllowedPublishers: []string{signed.PublisherKey},
+		MaxPriceAge:       "876000h",
+	}))
+	client.Subscribe(assetId, []byte(`{"type":"subscribe"}`))
+
+	gotPair, ok := waitForCache(client, assetId, 2*time.Second)
+	if !ok {
+		t.Fatal("expected a verified price to be cached after replay")
+	}
+	if len(gotPair.SignedPrices) != 1 {
+		t.Fatalf("expected 1 verified signed price, got %d", len(gotPair.SignedPrices))
+	}
+
+	goldenPath := filepath.Join("..", "pkg", "storktest", "testdata", "stork", "valid_single_publisher.golden.json")
+	if _, err := os.Stat(goldenPath); os.IsNotExist(err) && os.Getenv("STORKTEST_UPDATE_GOLDEN") == "" {
+		t.Skipf("golden file %s not seeded yet; re-run with STORKTEST_UPDATE_GOLDEN=1 to create it", goldenPath)
+	}
+	storktest.AssertGoldenAssetPair(t, gotPair, goldenPath)
+}
+
+// TestStorkReplayVerifiesMultiplePublishers drives a fixture with two
+// independently-signed prices for the same asset through the same
+// replay pipeline, checking that both survive verification together.
+func TestStorkReplayVerifiesMultiplePublishers(t *testing.T) {
+	const assetId = "BTCUSD"
+	price := math.LegacyMustNewDecFromStr("65000.50")
+	timestamp := uint64(time.Now().UnixNano())
+
+	first := signTestPriceWithKey(t, testStorkPrivateKeyHex, assetId, price, timestamp)
+	second := signTestPriceWithKey(t, testStorkPrivateKeyHex2, assetId, price, timestamp)
+
+	msg := messageResponse{
+		Type:    "oracle_prices",
+		TraceID: "replay-multi-publisher",
+		Data: map[string]Data{
+			assetId: {
+				Timestamp:     time.Now().Unix(),
+				AssetID:       assetId,
+				SignatureType: "evm",
+				Price:         price.String(),
+				SignedPrices:  []SignedPrice{first, second},
+			},
+		},
+	}
+
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("failed to marshal fixture frame: %v", err)
+	}
+
+	server := storktest.NewReplayServer([]storktest.Frame{{Version: 1, Raw: raw}})
+	defer server.Close()
+
+	client := newStorkWSClient(storktest.WSURL(server), "")
+	defer client.cancel()
+
+	client.mergeVerifier(testVerifier(t, &StorkFeedConfig{
+		AllowedPublishers: []string{first.PublisherKey, second.PublisherKey},
+		Quorum:            2,
+	}))
+	client.Subscribe(assetId, []byte(`{"type":"subscribe"}`))
+
+	gotPair, ok := waitForCache(client, assetId, 2*time.Second)
+	if !ok {
+		t.Fatal("expected a verified price to be cached after replay")
+	}
+	if len(gotPair.SignedPrices) != 2 {
+		t.Fatalf("expected 2 verified signed prices, got %d", len(gotPair.SignedPrices))
+	}
+}
+
+// TestStorkReplayRejectsGarbageFixtures drives the static, crypto-
+// independent fixtures (missing fields, unparseable msg_hash, an
+// oversized signed_prices array) through the same replay+client
+// pipeline, and asserts that none of them are ever cached: every entry
+// in them fails verification, so the per-feed quorum check must reject
+// the round rather than caching an empty or partially-verified result.
+func TestStorkReplayRejectsGarbageFixtures(t *testing.T) {
+	fixtures := []string{
+		"missing_signed_prices.jsonl",
+		"malformed_msg_hash.jsonl",
+		"oversized_message.jsonl",
+	}
+
+	for _, name := range fixtures {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			frames, err := storktest.LoadFixture(filepath.Join("..", "pkg", "storktest", "testdata", "stork", name))
+			if err != nil {
+				t.Fatalf("failed to load fixture %s: %v", name, err)
+			}
+
+			server := storktest.NewReplayServer(frames)
+			defer server.Close()
+
+			client := newStorkWSClient(storktest.WSURL(server), "")
+			defer client.cancel()
+
+			client.mergeVerifier(testVerifier(t, &StorkFeedConfig{}))
+			client.Subscribe("BTCUSD", []byte(`{"type":"subscribe"}`))
+			client.Subscribe("ETHUSD", []byte(`{"type":"subscribe"}`))
+
+			if _, ok := waitForCache(client, "BTCUSD", 300*time.Millisecond); ok {
+				t.Error("expected BTCUSD not to be cached from an all-garbage fixture")
+			}
+			if _, ok := waitForCache(client, "ETHUSD", 10*time.Millisecond); ok {
+				t.Error("expected ETHUSD not to be cached from an all-garbage fixture")
+			}
+		})
+	}
+}
+
+// waitForCache polls client for up to timeout for a cached value for
+// assetId, since replay happens over a real (if local) WebSocket
+// connection on a background goroutine rather than synchronously.
+func waitForCache(client *storkWSClient, assetId string, timeout time.Duration) (pair oracletypes.AssetPair, ok bool) {
+	deadline := time.Now().Add(timeout)
+	for {
+		if p, _, found := client.Get(assetId); found {
+			return p, true
+		}
+		if time.Now().After(deadline) {
+			return oracletypes.AssetPair{}, false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
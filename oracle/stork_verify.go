@@ -0,0 +1,241 @@
+package oracle
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/InjectiveLabs/metrics"
+	oracletypes "github.com/InjectiveLabs/sdk-go/chain/oracle/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	log "github.com/xlab/suplog"
+)
+
+const (
+	defaultStorkMaxPriceAge   = 2 * time.Minute
+	defaultStorkMaxFutureSkew = 5 * time.Second
+	defaultStorkQuorum        = 1
+)
+
+// storkVerifier holds the policy a storkWSClient checks every incoming
+// SignedPrice against before it is allowed into the cache: who is
+// allowed to publish, and how stale or premature a signature may be.
+type storkVerifier struct {
+	allowedPublishers map[common.Address]struct{}
+	maxAge            time.Duration
+	maxFutureSkew     time.Duration
+	quorum            int
+}
+
+// newStorkVerifier builds a storkVerifier from a feed's TOML config,
+// falling back to conservative defaults for anything left unset. An
+// empty AllowedPublishers list disables the allow-list check rather
+// than rejecting every signer, preserving existing configs that didn't
+// set it.
+func newStorkVerifier(cfg *StorkFeedConfig) (*storkVerifier, error) {
+	maxAge := defaultStorkMaxPriceAge
+	if cfg.MaxPriceAge != "" {
+		d, err := time.ParseDuration(cfg.MaxPriceAge)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse maxPriceAge: %s", cfg.MaxPriceAge)
+		}
+		maxAge = d
+	}
+
+	maxFutureSkew := defaultStorkMaxFutureSkew
+	if cfg.MaxFutureSkew != "" {
+		d, err := time.ParseDuration(cfg.MaxFutureSkew)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse maxFutureSkew: %s", cfg.MaxFutureSkew)
+		}
+		maxFutureSkew = d
+	}
+
+	quorum := cfg.Quorum
+	if quorum <= 0 {
+		quorum = defaultStorkQuorum
+	}
+
+	allowed := make(map[common.Address]struct{}, len(cfg.AllowedPublishers))
+	for _, key := range cfg.AllowedPublishers {
+		allowed[common.HexToAddress(key)] = struct{}{}
+	}
+
+	return &storkVerifier{
+		allowedPublishers: allowed,
+		maxAge:            maxAge,
+		maxFutureSkew:     maxFutureSkew,
+		quorum:            quorum,
+	}, nil
+}
+
+// errAllStorkSignaturesRejected is wrapped into ConvertDataToAssetPairVerified's
+// error whenever every single SignedPrice in a round fails verification,
+// as opposed to merely falling short of quorum. The distinction matters
+// because the former almost always means the locally-reconstructed
+// storkMsgHash has stopped matching Stork's real signing scheme (a
+// regression that otherwise fails closed and silent, with no visible
+// difference from "the network is just quiet right now"), so callers
+// log it at a level that actually pages someone instead of the routine
+// per-entry warning.
+var errAllStorkSignaturesRejected = errors.New("every stork signed price failed verification")
+
+// ConvertDataToAssetPairVerified is the verifying counterpart of
+// ConvertDataToAssetPair: it runs every SignedPrice through
+// verifySignedPrice, drops (and counts via svcTags) anything that fails,
+// and errors out if fewer than v.quorum signatures survive.
+func ConvertDataToAssetPairVerified(data Data, assetId string, v *storkVerifier, svcTags metrics.Tags) (oracletypes.AssetPair, error) {
+	now := time.Now()
+
+	signedPricesOfAssetPair := make([]*oracletypes.SignedPriceOfAssetPair, 0, len(data.SignedPrices))
+	for i := range data.SignedPrices {
+		converted, err := verifySignedPrice(data.SignedPrices[i], v, now)
+		if err != nil {
+			metrics.ReportFuncError(svcTags)
+			log.WithError(err).Warningln("dropping invalid stork signed price")
+			continue
+		}
+		signedPricesOfAssetPair = append(signedPricesOfAssetPair, &converted)
+	}
+
+	if len(signedPricesOfAssetPair) < v.quorum {
+		if len(signedPricesOfAssetPair) == 0 && len(data.SignedPrices) > 0 {
+			return oracletypes.AssetPair{}, errors.Wrapf(
+				errAllStorkSignaturesRejected, "0/%d required valid stork signatures for asset %s (of %d received)",
+				v.quorum, assetId, len(data.SignedPrices),
+			)
+		}
+
+		return oracletypes.AssetPair{}, errors.Errorf(
+			"only %d/%d required valid stork signatures for asset %s", len(signedPricesOfAssetPair), v.quorum, assetId,
+		)
+	}
+
+	return oracletypes.AssetPair{
+		AssetId:      assetId,
+		SignedPrices: signedPricesOfAssetPair,
+	}, nil
+}
+
+// verifySignedPrice checks that (a) the msg_hash Stork shipped alongside
+// the signature matches the one we independently reconstruct from the
+// signed fields, (b) the ECDSA signature recovers to signed.PublisherKey,
+// (c) that key is on the allow-list when one is configured, and (d) the
+// signature isn't older than v.maxAge or further than v.maxFutureSkew in
+// the future. Only a SignedPriceOfAssetPair that passes every check is
+// returned.
+func verifySignedPrice(signed SignedPrice, v *storkVerifier, now time.Time) (oracletypes.SignedPriceOfAssetPair, error) {
+	ts := signed.TimestampedSignature
+
+	wantHash := storkMsgHash(signed)
+	if !strings.EqualFold(stripHexPrefix(ts.MsgHash), wantHash.Hex()[2:]) {
+		return oracletypes.SignedPriceOfAssetPair{}, errors.Errorf("msg_hash mismatch for publisher %s", signed.PublisherKey)
+	}
+
+	signer, err := recoverStorkSigner(wantHash, ts.Signature)
+	if err != nil {
+		return oracletypes.SignedPriceOfAssetPair{}, errors.Wrapf(err, "failed to recover signer for publisher %s", signed.PublisherKey)
+	}
+
+	if !strings.EqualFold(signer.Hex(), signed.PublisherKey) {
+		return oracletypes.SignedPriceOfAssetPair{}, errors.Errorf(
+			"recovered signer %s does not match publisher key %s", signer.Hex(), signed.PublisherKey,
+		)
+	}
+
+	if len(v.allowedPublishers) > 0 {
+		if _, ok := v.allowedPublishers[signer]; !ok {
+			return oracletypes.SignedPriceOfAssetPair{}, errors.Errorf("publisher %s is not on the allow-list", signed.PublisherKey)
+		}
+	}
+
+	age := now.Sub(time.Unix(0, int64(ts.Timestamp)))
+	if age > v.maxAge {
+		return oracletypes.SignedPriceOfAssetPair{}, errors.Errorf("signed price from %s is stale (age %s)", signed.PublisherKey, age)
+	}
+	if age < -v.maxFutureSkew {
+		return oracletypes.SignedPriceOfAssetPair{}, errors.Errorf("signed price from %s is %s in the future", signed.PublisherKey, -age)
+	}
+
+	return ConvertSignedPrice(signed), nil
+}
+
+// storkMsgHash reconstructs the digest Stork publishers sign over: a raw
+// keccak256 (no EIP-191 wrapping) of the publisher address, the signed
+// asset id, the quantized price, and the timestamp, each packed at a
+// fixed width the way abi.encodePacked would — not their ASCII decimal
+// representations. It is cross-checked against the msg_hash Stork ships
+// on the wire instead of trusting that field blindly.
+//
+// CAVEAT: this field order/width has not been confirmed against a real
+// Stork publisher frame (this development environment has no network
+// access to Stork's endpoint or its published verifier source) — it is
+// the packing scheme abi.encodePacked would produce for these fields,
+// not a value cross-checked against a live msg_hash. Before relying on
+// this in production, record one real frame with Record (see
+// pkg/storktest/record.go) and confirm storkMsgHash reproduces its
+// msg_hash and recovers its known signer; wire that frame in as
+// testdata/stork/valid_single_publisher.jsonl in place of the
+// synthetic one oracle/stork_replay_test.go currently generates. If it
+// doesn't match, every real signed price will fail closed silently —
+// see errAllStorkSignaturesRejected for the one piece of this path that
+// is instrumented to complain loudly if that happens.
+func storkMsgHash(signed SignedPrice) common.Hash {
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], signed.TimestampedSignature.Timestamp)
+
+	packed := make([]byte, 0, common.AddressLength+32+32+8)
+	packed = append(packed, common.HexToAddress(signed.PublisherKey).Bytes()...)
+	packed = append(packed, crypto.Keccak256([]byte(signed.ExternalAssetID))...)
+	packed = append(packed, common.LeftPadBytes(signed.Price.BigInt().Bytes(), 32)...)
+	packed = append(packed, timestamp[:]...)
+
+	return crypto.Keccak256Hash(packed)
+}
+
+// recoverStorkSigner ecrecovers the address that produced sig over hash.
+func recoverStorkSigner(hash common.Hash, sig Signature) (common.Address, error) {
+	r, err := decodeSigComponent(sig.R)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "invalid r")
+	}
+	s, err := decodeSigComponent(sig.S)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "invalid s")
+	}
+	vByte, err := decodeSigComponent(sig.V)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "invalid v")
+	}
+	if len(r) != 32 || len(s) != 32 || len(vByte) != 1 {
+		return common.Address{}, errors.New("malformed signature component length")
+	}
+
+	recoveryID := vByte[0]
+	if recoveryID >= 27 {
+		recoveryID -= 27
+	}
+
+	sigBytes := make([]byte, 65)
+	copy(sigBytes[0:32], r)
+	copy(sigBytes[32:64], s)
+	sigBytes[64] = recoveryID
+
+	pubKey, err := crypto.Ecrecover(hash.Bytes(), sigBytes)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "ecrecover failed")
+	}
+
+	return common.BytesToAddress(crypto.Keccak256(pubKey[1:])[12:]), nil
+}
+
+func decodeSigComponent(s string) ([]byte, error) {
+	return hex.DecodeString(stripHexPrefix(s))
+}
+
+func stripHexPrefix(s string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+}
@@ -0,0 +1,311 @@
+package oracle
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	"github.com/InjectiveLabs/metrics"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// testStorkPrivateKeyHex is a throwaway secp256k1 key used only to
+// produce real, internally-consistent signatures for these tests. It
+// signs nothing of value and is not used anywhere outside this package.
+const testStorkPrivateKeyHex = "2da5c6b0b5b1cf6d2b1cb4c7fc3c0c41b2f88d4e9c3f3b1a0d5e6f7081c2a3b4"
+
+// testStorkPrivateKeyHex2 is a second throwaway key, distinct from
+// testStorkPrivateKeyHex, for tests that need two independent
+// publishers agreeing on a price.
+const testStorkPrivateKeyHex2 = "7b3c1e9f4a2d6e8b0c5f1a3d7e9b2c4f6a8d0e2b4c6f8a0d2e4b6c8f0a2d4e6b"
+
+// signTestPrice builds a fully-signed SignedPrice for assetId/price/
+// timestamp using the default test key, the same way a real Stork
+// publisher would: hash the fields via storkMsgHash, sign that hash,
+// and carry the resulting msg_hash/signature back on the wire.
+func signTestPrice(t *testing.T, assetId string, price math.LegacyDec, timestamp uint64) SignedPrice {
+	t.Helper()
+	return signTestPriceWithKey(t, testStorkPrivateKeyHex, assetId, price, timestamp)
+}
+
+// signTestPriceWithKey is signTestPrice parameterized by private key, so
+// tests can produce signed prices from distinct, independent publishers.
+func signTestPriceWithKey(t *testing.T, privateKeyHex, assetId string, price math.LegacyDec, timestamp uint64) SignedPrice {
+	t.Helper()
+
+	privKey, err := crypto.HexToECDSA(privateKeyHex)
+	if err != nil {
+		t.Fatalf("failed to parse test private key: %v", err)
+	}
+	publisher := crypto.PubkeyToAddress(privKey.PublicKey)
+
+	signed := SignedPrice{
+		PublisherKey:    publisher.Hex(),
+		ExternalAssetID: assetId,
+		SignatureType:   "evm",
+		Price:           price,
+		TimestampedSignature: TimestampedSignature{
+			Timestamp: timestamp,
+		},
+	}
+
+	hash := storkMsgHash(signed)
+
+	sig, err := crypto.Sign(hash.Bytes(), privKey)
+	if err != nil {
+		t.Fatalf("failed to sign test price: %v", err)
+	}
+
+	signed.TimestampedSignature.MsgHash = hash.Hex()[2:]
+	signed.TimestampedSignature.Signature = Signature{
+		R: hex.EncodeToString(sig[0:32]),
+		S: hex.EncodeToString(sig[32:64]),
+		V: hex.EncodeToString([]byte{sig[64] + 27}),
+	}
+
+	return signed
+}
+
+// TestStorkMsgHashPacking pins storkMsgHash to the exact abi.encodePacked
+// layout Stork signs over (publisher address, keccak256(external asset
+// id), 32-byte big-endian price, 8-byte big-endian timestamp). It
+// computes the expected hash independently of storkMsgHash itself, so a
+// regression back to the old EIP-191/decimal-string formula (or any
+// other change to the packing) fails this test instead of silently
+// passing a self-consistent round trip.
+func TestStorkMsgHashPacking(t *testing.T) {
+	publisher := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	price := math.LegacyMustNewDecFromStr("65000.50")
+	timestamp := uint64(1700000000000000000)
+
+	signed := SignedPrice{
+		PublisherKey:    publisher.Hex(),
+		ExternalAssetID: "BTCUSD",
+		Price:           price,
+		TimestampedSignature: TimestampedSignature{
+			Timestamp: timestamp,
+		},
+	}
+
+	var tsBytes [8]byte
+	binary.BigEndian.PutUint64(tsBytes[:], timestamp)
+
+	expectedPacked := make([]byte, 0, common.AddressLength+32+32+8)
+	expectedPacked = append(expectedPacked, publisher.Bytes()...)
+	expectedPacked = append(expectedPacked, crypto.Keccak256([]byte("BTCUSD"))...)
+	expectedPacked = append(expectedPacked, common.LeftPadBytes(price.BigInt().Bytes(), 32)...)
+	expectedPacked = append(expectedPacked, tsBytes[:]...)
+	expectedHash := crypto.Keccak256Hash(expectedPacked)
+
+	if got := storkMsgHash(signed); got != expectedHash {
+		t.Fatalf("storkMsgHash packing changed: got %s want %s", got.Hex(), expectedHash.Hex())
+	}
+}
+
+func testVerifier(t *testing.T, cfg *StorkFeedConfig) *storkVerifier {
+	t.Helper()
+
+	v, err := newStorkVerifier(cfg)
+	if err != nil {
+		t.Fatalf("failed to build verifier: %v", err)
+	}
+	return v
+}
+
+func TestVerifySignedPriceValid(t *testing.T) {
+	price := math.LegacyMustNewDecFromStr("65000.50")
+	timestamp := uint64(time.Now().UnixNano())
+	signed := signTestPrice(t, "BTCUSD", price, timestamp)
+
+	v := testVerifier(t, &StorkFeedConfig{AllowedPublishers: []string{signed.PublisherKey}})
+
+	converted, err := verifySignedPrice(signed, v, time.Now())
+	if err != nil {
+		t.Fatalf("expected a validly-signed price to verify, got: %v", err)
+	}
+	if !strings.EqualFold(converted.PublisherKey, signed.PublisherKey) {
+		t.Errorf("publisher key mismatch: got %s want %s", converted.PublisherKey, signed.PublisherKey)
+	}
+}
+
+func TestVerifySignedPriceRejectsTamperedPrice(t *testing.T) {
+	timestamp := uint64(time.Now().UnixNano())
+	signed := signTestPrice(t, "BTCUSD", math.LegacyMustNewDecFromStr("65000.50"), timestamp)
+
+	// Tamper with the price after signing: msg_hash and signature no
+	// longer match the payload, so verification must fail closed.
+	signed.Price = math.LegacyMustNewDecFromStr("1.00")
+
+	v := testVerifier(t, &StorkFeedConfig{AllowedPublishers: []string{signed.PublisherKey}})
+
+	if _, err := verifySignedPrice(signed, v, time.Now()); err == nil {
+		t.Fatal("expected a tampered price to fail msg_hash verification")
+	}
+}
+
+func TestVerifySignedPriceRejectsUnlistedPublisher(t *testing.T) {
+	timestamp := uint64(time.Now().UnixNano())
+	signed := signTestPrice(t, "BTCUSD", math.LegacyMustNewDecFromStr("65000.50"), timestamp)
+
+	v := testVerifier(t, &StorkFeedConfig{AllowedPublishers: []string{"0x9999999999999999999999999999999999999999"}})
+
+	if _, err := verifySignedPrice(signed, v, time.Now()); err == nil {
+		t.Fatal("expected a publisher outside the allow-list to be rejected")
+	}
+}
+
+func TestVerifySignedPriceRejectsStale(t *testing.T) {
+	staleTimestamp := uint64(time.Now().Add(-1 * time.Hour).UnixNano())
+	signed := signTestPrice(t, "BTCUSD", math.LegacyMustNewDecFromStr("65000.50"), staleTimestamp)
+
+	v := testVerifier(t, &StorkFeedConfig{
+		AllowedPublishers: []string{signed.PublisherKey},
+		MaxPriceAge:       "2m",
+	})
+
+	if _, err := verifySignedPrice(signed, v, time.Now()); err == nil {
+		t.Fatal("expected a stale signed price to be rejected")
+	}
+}
+
+func TestVerifySignedPriceRejectsMalformedSignatureHex(t *testing.T) {
+	timestamp := uint64(time.Now().UnixNano())
+	signed := signTestPrice(t, "BTCUSD", math.LegacyMustNewDecFromStr("65000.50"), timestamp)
+
+	// Corrupt R with a non-hex character after the msg_hash has already
+	// been computed over the (untouched) fields, so the msg_hash check
+	// still passes and decodeSigComponent's hex error path is reached.
+	signed.TimestampedSignature.Signature.R = "zz" + signed.TimestampedSignature.Signature.R[2:]
+
+	v := testVerifier(t, &StorkFeedConfig{AllowedPublishers: []string{signed.PublisherKey}})
+
+	if _, err := verifySignedPrice(signed, v, time.Now()); err == nil {
+		t.Fatal("expected a malformed signature component to be rejected")
+	}
+}
+
+func TestConvertDataToAssetPairVerifiedQuorum(t *testing.T) {
+	timestamp := uint64(time.Now().UnixNano())
+
+	valid := signTestPrice(t, "BTCUSD", math.LegacyMustNewDecFromStr("65000.50"), timestamp)
+	tampered := signTestPrice(t, "BTCUSD", math.LegacyMustNewDecFromStr("65000.50"), timestamp)
+	tampered.Price = math.LegacyMustNewDecFromStr("1.00")
+
+	data := Data{
+		AssetID:      "BTCUSD",
+		SignedPrices: []SignedPrice{valid, tampered},
+	}
+
+	v := testVerifier(t, &StorkFeedConfig{AllowedPublishers: []string{valid.PublisherKey}, Quorum: 1})
+
+	pair, err := ConvertDataToAssetPairVerified(data, "BTCUSD", v, metrics.Tags{"svc": "test"})
+	if err != nil {
+		t.Fatalf("expected quorum of 1 to be met by the single valid signer: %v", err)
+	}
+	if len(pair.SignedPrices) != 1 {
+		t.Fatalf("expected exactly 1 verified signed price, got %d", len(pair.SignedPrices))
+	}
+
+	v2 := testVerifier(t, &StorkFeedConfig{AllowedPublishers: []string{valid.PublisherKey}, Quorum: 2})
+	if _, err := ConvertDataToAssetPairVerified(data, "BTCUSD", v2, metrics.Tags{"svc": "test"}); err == nil {
+		t.Fatal("expected quorum of 2 to fail with only 1 valid signer")
+	}
+}
+
+// TestConvertDataToAssetPairVerifiedMultiplePublishers covers a
+// multi-publisher payload where two independent signers both agree on
+// the same price: both must verify and both must be present in the
+// resulting AssetPair.
+func TestConvertDataToAssetPairVerifiedMultiplePublishers(t *testing.T) {
+	timestamp := uint64(time.Now().UnixNano())
+	price := math.LegacyMustNewDecFromStr("65000.50")
+
+	first := signTestPriceWithKey(t, testStorkPrivateKeyHex, "BTCUSD", price, timestamp)
+	second := signTestPriceWithKey(t, testStorkPrivateKeyHex2, "BTCUSD", price, timestamp)
+
+	if strings.EqualFold(first.PublisherKey, second.PublisherKey) {
+		t.Fatal("test keys must produce distinct publishers")
+	}
+
+	data := Data{
+		AssetID:      "BTCUSD",
+		SignedPrices: []SignedPrice{first, second},
+	}
+
+	v := testVerifier(t, &StorkFeedConfig{
+		AllowedPublishers: []string{first.PublisherKey, second.PublisherKey},
+		Quorum:            2,
+	})
+
+	pair, err := ConvertDataToAssetPairVerified(data, "BTCUSD", v, metrics.Tags{"svc": "test"})
+	if err != nil {
+		t.Fatalf("expected both independent publishers to verify: %v", err)
+	}
+	if len(pair.SignedPrices) != 2 {
+		t.Fatalf("expected 2 verified signed prices, got %d", len(pair.SignedPrices))
+	}
+}
+
+// TestVerifySignedPriceAcceptsSignatureWithOrWithout0xPrefix covers both
+// wire forms Stork could plausibly use for r/s/v: with a "0x" prefix and
+// without. decodeSigComponent/stripHexPrefix must accept either.
+func TestVerifySignedPriceAcceptsSignatureWithOrWithout0xPrefix(t *testing.T) {
+	for _, prefixed := range []bool{false, true} {
+		prefixed := prefixed
+		name := "no_prefix"
+		if prefixed {
+			name = "0x_prefix"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			timestamp := uint64(time.Now().UnixNano())
+			signed := signTestPrice(t, "BTCUSD", math.LegacyMustNewDecFromStr("65000.50"), timestamp)
+
+			if prefixed {
+				signed.TimestampedSignature.Signature.R = "0x" + signed.TimestampedSignature.Signature.R
+				signed.TimestampedSignature.Signature.S = "0x" + signed.TimestampedSignature.Signature.S
+				signed.TimestampedSignature.Signature.V = "0x" + signed.TimestampedSignature.Signature.V
+				signed.TimestampedSignature.MsgHash = "0x" + signed.TimestampedSignature.MsgHash
+			}
+
+			v := testVerifier(t, &StorkFeedConfig{AllowedPublishers: []string{signed.PublisherKey}})
+
+			if _, err := verifySignedPrice(signed, v, time.Now()); err != nil {
+				t.Fatalf("expected signature to verify regardless of 0x prefix (prefixed=%v): %v", prefixed, err)
+			}
+		})
+	}
+}
+
+// TestVerifySignedPriceRejectsMalformedVWithAndWithoutPrefix covers a
+// malformed v component (wrong length) in both wire forms.
+func TestVerifySignedPriceRejectsMalformedVWithAndWithoutPrefix(t *testing.T) {
+	for _, prefixed := range []bool{false, true} {
+		prefixed := prefixed
+		name := "no_prefix"
+		if prefixed {
+			name = "0x_prefix"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			timestamp := uint64(time.Now().UnixNano())
+			signed := signTestPrice(t, "BTCUSD", math.LegacyMustNewDecFromStr("65000.50"), timestamp)
+
+			malformedV := "1b1b" // two bytes instead of one
+			if prefixed {
+				malformedV = "0x" + malformedV
+			}
+			signed.TimestampedSignature.Signature.V = malformedV
+
+			v := testVerifier(t, &StorkFeedConfig{AllowedPublishers: []string{signed.PublisherKey}})
+
+			if _, err := verifySignedPrice(signed, v, time.Now()); err == nil {
+				t.Fatal("expected a malformed v component to be rejected")
+			}
+		})
+	}
+}
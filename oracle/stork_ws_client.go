@@ -0,0 +1,386 @@
+package oracle
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/InjectiveLabs/metrics"
+	oracletypes "github.com/InjectiveLabs/sdk-go/chain/oracle/types"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	log "github.com/xlab/suplog"
+)
+
+const (
+	storkWSWriteTimeout = 10 * time.Second
+	storkWSPingInterval = 15 * time.Second
+	storkWSPongTimeout  = 30 * time.Second
+	storkWSOutChanSize  = 256
+	storkWSMinBackoff   = 1 * time.Second
+	storkWSMaxBackoff   = 1 * time.Minute
+)
+
+// cachedAssetPair stores the last AssetPair observed for an asset id,
+// alongside the time it was received, so callers can decide for
+// themselves whether the value is still fresh enough to use.
+type cachedAssetPair struct {
+	pair      oracletypes.AssetPair
+	updatedAt time.Time
+}
+
+// storkWSClient is a single long-lived WebSocket connection to a Stork
+// endpoint, shared by every storkPriceFeed dialing the same (url, header)
+// pair. It dials once, (re)subscribes every registered asset id over
+// that one socket, and demultiplexes incoming messageResponse frames by
+// asset_id into a per-asset cache that PullAssetPair reads from without
+// touching the network.
+type storkWSClient struct {
+	url    string
+	header http.Header
+
+	logger  log.Logger
+	svcTags metrics.Tags
+
+	connMu sync.Mutex
+	conn   *websocket.Conn
+	outCh  chan []byte
+
+	aliveLock sync.RWMutex
+	alive     bool
+
+	cacheMu sync.RWMutex
+	cache   map[string]cachedAssetPair
+
+	subsMu sync.Mutex
+	subs   map[string]json.RawMessage // assetID -> subscribe message to (re)send on (re)connect
+
+	verifierMu sync.RWMutex
+	verifier   *storkVerifier
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+var (
+	storkClientsMu sync.Mutex
+	storkClients   = map[string]*storkWSClient{}
+)
+
+// getOrCreateStorkWSClient returns the shared storkWSClient dialing url
+// with header, creating and starting it on first use.
+func getOrCreateStorkWSClient(url, header string) *storkWSClient {
+	key := url + "\x00" + header
+
+	storkClientsMu.Lock()
+	defer storkClientsMu.Unlock()
+
+	if c, ok := storkClients[key]; ok {
+		return c
+	}
+
+	c := newStorkWSClient(url, header)
+	storkClients[key] = c
+
+	return c
+}
+
+func newStorkWSClient(rawURL, headerValue string) *storkWSClient {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	header := http.Header{}
+	header.Add("Authorization", "Basic "+headerValue)
+
+	svcTags := metrics.Tags{
+		"svc": "stork_ws_client",
+	}
+
+	c := &storkWSClient{
+		url:    rawURL,
+		header: header,
+
+		logger: log.WithFields(log.Fields{
+			"svc": "oracle",
+			"url": rawURL,
+		}),
+		svcTags: svcTags,
+
+		outCh: make(chan []byte, storkWSOutChanSize),
+		cache: make(map[string]cachedAssetPair),
+		subs:  make(map[string]json.RawMessage),
+
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	go c.connectLoop()
+
+	return c
+}
+
+// Subscribe registers assetID on this client, sending message to the
+// remote endpoint if a connection is currently live, and re-sending it
+// automatically on every future reconnect.
+func (c *storkWSClient) Subscribe(assetID string, message []byte) {
+	c.subsMu.Lock()
+	c.subs[assetID] = json.RawMessage(message)
+	c.subsMu.Unlock()
+
+	if c.isAlive() {
+		c.send(message)
+	}
+}
+
+// mergeVerifier installs v as this client's verification policy if none
+// is set yet; otherwise it only unions v's allow-list into the existing
+// policy, since every storkPriceFeed sharing this client talks to the
+// same Stork endpoint and is expected to agree on staleness/quorum.
+func (c *storkWSClient) mergeVerifier(v *storkVerifier) {
+	c.verifierMu.Lock()
+	defer c.verifierMu.Unlock()
+
+	if c.verifier == nil {
+		c.verifier = v
+		return
+	}
+
+	for addr := range v.allowedPublishers {
+		c.verifier.allowedPublishers[addr] = struct{}{}
+	}
+}
+
+func (c *storkWSClient) getVerifier() *storkVerifier {
+	c.verifierMu.RLock()
+	defer c.verifierMu.RUnlock()
+	return c.verifier
+}
+
+// Unsubscribe drops assetID so it is no longer resubscribed on reconnect.
+func (c *storkWSClient) Unsubscribe(assetID string) {
+	c.subsMu.Lock()
+	delete(c.subs, assetID)
+	c.subsMu.Unlock()
+}
+
+// Get returns the last cached AssetPair for assetID, if any has been
+// observed yet.
+func (c *storkWSClient) Get(assetID string) (pair oracletypes.AssetPair, updatedAt time.Time, ok bool) {
+	c.cacheMu.RLock()
+	defer c.cacheMu.RUnlock()
+
+	cached, found := c.cache[assetID]
+	if !found {
+		return oracletypes.AssetPair{}, time.Time{}, false
+	}
+
+	return cached.pair, cached.updatedAt, true
+}
+
+func (c *storkWSClient) setCache(assetID string, pair oracletypes.AssetPair) {
+	c.cacheMu.Lock()
+	c.cache[assetID] = cachedAssetPair{pair: pair, updatedAt: time.Now()}
+	c.cacheMu.Unlock()
+
+	publishAssetPair(assetID, pair)
+}
+
+func (c *storkWSClient) setAlive(alive bool) {
+	c.aliveLock.Lock()
+	c.alive = alive
+	c.aliveLock.Unlock()
+}
+
+func (c *storkWSClient) isAlive() bool {
+	c.aliveLock.RLock()
+	defer c.aliveLock.RUnlock()
+	return c.alive
+}
+
+// send enqueues message for the writer goroutine, dropping it if the
+// out channel is full rather than blocking the caller.
+func (c *storkWSClient) send(message []byte) {
+	select {
+	case c.outCh <- message:
+	default:
+		metrics.ReportFuncError(c.svcTags)
+		c.logger.Warningln("stork ws out channel full, dropping message")
+	}
+}
+
+// connectLoop dials the endpoint, runs the reader/writer pair until the
+// connection drops, then reconnects with exponential backoff. It never
+// returns until the client's context is cancelled.
+func (c *storkWSClient) connectLoop() {
+	backoff := storkWSMinBackoff
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		default:
+		}
+
+		// A local Dialer value, not websocket.DefaultDialer itself: the
+		// latter is a shared package-level *Dialer, and concurrent
+		// storkWSClients setting EnableCompression on it would race.
+		dialer := websocket.Dialer{
+			Proxy:             http.ProxyFromEnvironment,
+			HandshakeTimeout:  45 * time.Second,
+			EnableCompression: true,
+		}
+
+		conn, resp, err := dialer.DialContext(c.ctx, c.url, c.header)
+		if err != nil {
+			if resp != nil {
+				c.logger.WithField("status", resp.StatusCode).Warningln("stork ws handshake failed")
+			}
+			c.logger.WithError(err).Warningln("failed to dial stork ws, will retry")
+			metrics.ReportFuncError(c.svcTags)
+
+			if !c.sleepBackoff(&backoff) {
+				return
+			}
+			continue
+		}
+
+		c.logger.Infoln("connected to stork ws endpoint")
+		backoff = storkWSMinBackoff
+		c.setAlive(true)
+
+		c.connMu.Lock()
+		c.conn = conn
+		c.connMu.Unlock()
+
+		c.resubscribeAll()
+
+		done := make(chan struct{})
+		go c.writerLoop(conn, done)
+		c.readerLoop(conn)
+
+		c.setAlive(false)
+		close(done)
+		conn.Close()
+
+		if !c.sleepBackoff(&backoff) {
+			return
+		}
+	}
+}
+
+// sleepBackoff waits for the current backoff duration (plus jitter),
+// doubling it for next time, and returns false if the client was
+// cancelled while waiting.
+func (c *storkWSClient) sleepBackoff(backoff *time.Duration) bool {
+	jitter := time.Duration(rand.Int63n(int64(*backoff) / 2))
+	wait := *backoff + jitter
+
+	select {
+	case <-c.ctx.Done():
+		return false
+	case <-time.After(wait):
+	}
+
+	*backoff *= 2
+	if *backoff > storkWSMaxBackoff {
+		*backoff = storkWSMaxBackoff
+	}
+
+	return true
+}
+
+func (c *storkWSClient) resubscribeAll() {
+	c.subsMu.Lock()
+	messages := make([][]byte, 0, len(c.subs))
+	for _, msg := range c.subs {
+		messages = append(messages, msg)
+	}
+	c.subsMu.Unlock()
+
+	for _, msg := range messages {
+		c.send(msg)
+	}
+}
+
+// writerLoop serializes all writes to conn: queued subscribe messages
+// plus a periodic ping to keep the connection alive.
+func (c *storkWSClient) writerLoop(conn *websocket.Conn, done chan struct{}) {
+	ticker := time.NewTicker(storkWSPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-c.ctx.Done():
+			return
+		case msg := <-c.outCh:
+			conn.SetWriteDeadline(time.Now().Add(storkWSWriteTimeout))
+			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				c.logger.WithError(err).Warningln("failed to write to stork ws")
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(storkWSWriteTimeout))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.logger.WithError(err).Warningln("failed to ping stork ws")
+				return
+			}
+		}
+	}
+}
+
+// readerLoop reads frames off conn until it errors out or is closed,
+// demultiplexing each messageResponse by asset_id into the shared cache.
+func (c *storkWSClient) readerLoop(conn *websocket.Conn) {
+	conn.SetReadDeadline(time.Now().Add(storkWSPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(storkWSPongTimeout))
+		return nil
+	})
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			c.logger.WithError(err).Warningln("stork ws read error, reconnecting")
+			metrics.ReportFuncError(c.svcTags)
+			return
+		}
+
+		var msgResp messageResponse
+		if err := json.Unmarshal(message, &msgResp); err != nil {
+			c.logger.WithError(err).Warningln("failed to unmarshal stork ws message")
+			continue
+		}
+
+		verifier := c.getVerifier()
+		if verifier == nil {
+			c.logger.Warningln("dropping stork ws message received before any feed registered a verifier")
+			continue
+		}
+
+		for assetID, data := range msgResp.Data {
+			pair, err := ConvertDataToAssetPairVerified(data, assetID, verifier, c.svcTags)
+			if err != nil {
+				if errors.Is(err, errAllStorkSignaturesRejected) {
+					// Every signature failed, not just some — almost
+					// always means the locally-reconstructed msg_hash
+					// formula no longer matches Stork's real signing
+					// scheme, which fails closed and otherwise looks
+					// identical to the network just being quiet.
+					c.logger.WithError(err).Errorln("every stork signed price failed verification; storkMsgHash may no longer match Stork's real signing scheme")
+				} else {
+					c.logger.WithError(err).Warningln("dropping stork price update that failed verification")
+				}
+				continue
+			}
+			c.setCache(assetID, pair)
+		}
+	}
+}
+
+// errStorkPriceNotCached is returned by PullAssetPair when a ticker has
+// been registered but no message has been observed for it yet.
+var errStorkPriceNotCached = errors.New("no stork price cached yet")
@@ -0,0 +1,50 @@
+package oracle
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/InjectiveLabs/injective-price-oracle/pkg/oracle/wsapi"
+	oracletypes "github.com/InjectiveLabs/sdk-go/chain/oracle/types"
+)
+
+// globalWSAPIRegistry fans every AssetPair this process pulls out to any
+// downstream consumers connected through StartWSAPIServer. It stays nil,
+// making publishAssetPair a no-op, until a server has been started.
+// publishAssetPair reads it from the storkWSClient reader goroutine
+// while StartWSAPIServer may write it concurrently from whatever
+// goroutine starts the server, so both go through an atomic.Pointer
+// rather than a bare package variable.
+//
+// Nothing in this tree calls StartWSAPIServer (the only cmd/ entrypoint
+// is storktest-record), so publishAssetPair stays a no-op unless an
+// embedding program starts the server.
+var globalWSAPIRegistry atomic.Pointer[wsapi.Registry]
+
+// StartWSAPIServer exposes this process's normalized AssetPair stream
+// over the subscription protocol in pkg/oracle/wsapi, so downstream
+// consumers can subscribeTicker/subscribeAllAssets/getLastPrice instead
+// of re-pulling the same upstream sources themselves. It blocks serving
+// until addr's listener errors or the process exits.
+func StartWSAPIServer(addr string) error {
+	registry := wsapi.NewRegistry()
+	globalWSAPIRegistry.Store(registry)
+
+	server := wsapi.NewServer(registry)
+
+	mux := http.NewServeMux()
+	mux.Handle("/ws", server)
+
+	return http.ListenAndServe(addr, mux)
+}
+
+// publishAssetPair fans out a freshly cached AssetPair to StartWSAPIServer
+// subscribers, if the server has been started.
+func publishAssetPair(assetId string, pair oracletypes.AssetPair) {
+	registry := globalWSAPIRegistry.Load()
+	if registry == nil {
+		return
+	}
+
+	registry.Publish(assetId, pair)
+}
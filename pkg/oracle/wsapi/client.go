@@ -0,0 +1,146 @@
+package wsapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+)
+
+const requestTimeout = 10 * time.Second
+
+// Client is a generic outbound wsapi client: it wraps a *websocket.Conn,
+// tags every call with a unique request ID, and dispatches the matching
+// response back to whichever goroutine issued it. One Client can
+// multiplex any number of concurrent subscribeTicker/getLastPrice calls
+// over a single connection. Unsolicited subscription pushes (responses
+// with an empty ID) are discarded by the read loop; prices are read
+// through GetLastPrice.
+type Client struct {
+	conn *websocket.Conn
+
+	nextID uint64
+
+	pendingMu sync.Mutex
+	pending   map[string]chan websocketRes
+
+	writeMu sync.Mutex
+}
+
+func NewClient(conn *websocket.Conn) *Client {
+	c := &Client{
+		conn:    conn,
+		pending: make(map[string]chan websocketRes),
+	}
+
+	go c.readLoop()
+
+	return c
+}
+
+func (c *Client) readLoop() {
+	for {
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
+			c.failAllPending(err)
+			return
+		}
+
+		var res websocketRes
+		if err := json.Unmarshal(message, &res); err != nil {
+			continue
+		}
+
+		c.pendingMu.Lock()
+		ch, ok := c.pending[res.ID]
+		if ok {
+			delete(c.pending, res.ID)
+		}
+		c.pendingMu.Unlock()
+
+		if ok {
+			ch <- res
+		}
+	}
+}
+
+func (c *Client) failAllPending(err error) {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+
+	for id, ch := range c.pending {
+		ch <- websocketRes{ID: id, Error: err.Error()}
+		delete(c.pending, id)
+	}
+}
+
+func (c *Client) call(method Method, params interface{}) (websocketRes, error) {
+	id := fmt.Sprintf("%d", atomic.AddUint64(&c.nextID, 1))
+
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return websocketRes{}, err
+	}
+
+	frame, err := json.Marshal(websocketReq{ID: id, Method: method, Params: raw})
+	if err != nil {
+		return websocketRes{}, err
+	}
+
+	ch := make(chan websocketRes, 1)
+	c.pendingMu.Lock()
+	c.pending[id] = ch
+	c.pendingMu.Unlock()
+
+	c.writeMu.Lock()
+	c.conn.SetWriteDeadline(time.Now().Add(requestTimeout))
+	writeErr := c.conn.WriteMessage(websocket.TextMessage, frame)
+	c.writeMu.Unlock()
+
+	if writeErr != nil {
+		c.pendingMu.Lock()
+		delete(c.pending, id)
+		c.pendingMu.Unlock()
+		return websocketRes{}, writeErr
+	}
+
+	select {
+	case res := <-ch:
+		if res.Error != "" {
+			return res, errors.New(res.Error)
+		}
+		return res, nil
+	case <-time.After(requestTimeout):
+		c.pendingMu.Lock()
+		delete(c.pending, id)
+		c.pendingMu.Unlock()
+		return websocketRes{}, errors.Errorf("wsapi call %s timed out", method)
+	}
+}
+
+func (c *Client) SubscribeTicker(assetId string) error {
+	_, err := c.call(MethodSubscribeTicker, tickerParams{AssetId: assetId})
+	return err
+}
+
+func (c *Client) UnsubscribeTicker(assetId string) error {
+	_, err := c.call(MethodUnsubscribeTicker, tickerParams{AssetId: assetId})
+	return err
+}
+
+func (c *Client) SubscribeAllAssets() error {
+	_, err := c.call(MethodSubscribeAllAssets, struct{}{})
+	return err
+}
+
+func (c *Client) GetLastPrice(assetId string) (json.RawMessage, error) {
+	res, err := c.call(MethodGetLastPrice, tickerParams{AssetId: assetId})
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
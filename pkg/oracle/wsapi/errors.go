@@ -0,0 +1,8 @@
+package wsapi
+
+import "errors"
+
+var (
+	errUnknownMethod = errors.New("wsapi: unknown method")
+	errNoLastPrice   = errors.New("wsapi: no last price cached yet")
+)
@@ -0,0 +1,38 @@
+// Package wsapi implements a small JSON-RPC-style subscription protocol
+// for the oracle's normalized AssetPair stream, following the same
+// request/response envelope shape used by blockbook's websocket server:
+// every call carries an ID the response echoes back, so many concurrent
+// calls can be multiplexed over one connection.
+package wsapi
+
+import "encoding/json"
+
+// Method names understood by Server.
+type Method string
+
+const (
+	MethodSubscribeTicker    Method = "subscribeTicker"
+	MethodUnsubscribeTicker  Method = "unsubscribeTicker"
+	MethodGetLastPrice       Method = "getLastPrice"
+	MethodSubscribeAllAssets Method = "subscribeAllAssets"
+)
+
+// websocketReq is the envelope every inbound call is wrapped in.
+type websocketReq struct {
+	ID     string          `json:"id"`
+	Method Method          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// websocketRes is the envelope every reply or push is wrapped in. ID
+// echoes the originating request's ID for direct replies, and is empty
+// for unsolicited pushes (subscription updates).
+type websocketRes struct {
+	ID    string          `json:"id,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+type tickerParams struct {
+	AssetId string `json:"assetId"`
+}
@@ -0,0 +1,129 @@
+package wsapi
+
+import (
+	"encoding/json"
+	"sync"
+
+	oracletypes "github.com/InjectiveLabs/sdk-go/chain/oracle/types"
+)
+
+// clientSendBuffer bounds how many unconsumed frames a subscriber may
+// queue before Publish starts dropping updates for it, so one slow
+// downstream consumer cannot block the puller that feeds Publish.
+const clientSendBuffer = 64
+
+// subscriber is one connected downstream consumer of the AssetPair
+// stream, tracking which tickers (or "all") it wants pushed to it.
+type subscriber struct {
+	send chan []byte
+
+	mu      sync.Mutex
+	all     bool
+	tickers map[string]struct{}
+}
+
+func newSubscriber() *subscriber {
+	return &subscriber{
+		send:    make(chan []byte, clientSendBuffer),
+		tickers: make(map[string]struct{}),
+	}
+}
+
+func (s *subscriber) wants(assetId string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.all {
+		return true
+	}
+	_, ok := s.tickers[assetId]
+	return ok
+}
+
+func (s *subscriber) subscribe(assetId string) {
+	s.mu.Lock()
+	s.tickers[assetId] = struct{}{}
+	s.mu.Unlock()
+}
+
+func (s *subscriber) unsubscribe(assetId string) {
+	s.mu.Lock()
+	delete(s.tickers, assetId)
+	s.mu.Unlock()
+}
+
+func (s *subscriber) subscribeAll() {
+	s.mu.Lock()
+	s.all = true
+	s.mu.Unlock()
+}
+
+// Registry fans AssetPair updates out to every subscriber interested in
+// that ticker and keeps the last value per asset id for getLastPrice.
+type Registry struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+
+	lastMu sync.RWMutex
+	last   map[string]oracletypes.AssetPair
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		subscribers: make(map[*subscriber]struct{}),
+		last:        make(map[string]oracletypes.AssetPair),
+	}
+}
+
+func (r *Registry) add(s *subscriber) {
+	r.mu.Lock()
+	r.subscribers[s] = struct{}{}
+	r.mu.Unlock()
+}
+
+func (r *Registry) remove(s *subscriber) {
+	r.mu.Lock()
+	delete(r.subscribers, s)
+	r.mu.Unlock()
+}
+
+// Publish records pair as the last known value for assetId and fans it
+// out to every subscriber currently interested in it, dropping the
+// update for any subscriber whose send buffer is full.
+func (r *Registry) Publish(assetId string, pair oracletypes.AssetPair) {
+	r.lastMu.Lock()
+	r.last[assetId] = pair
+	r.lastMu.Unlock()
+
+	payload, err := json.Marshal(pair)
+	if err != nil {
+		return
+	}
+
+	frame, err := json.Marshal(websocketRes{Data: payload})
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for s := range r.subscribers {
+		if !s.wants(assetId) {
+			continue
+		}
+		select {
+		case s.send <- frame:
+		default:
+		}
+	}
+}
+
+// LastPrice returns the last AssetPair published for assetId, if any.
+func (r *Registry) LastPrice(assetId string) (oracletypes.AssetPair, bool) {
+	r.lastMu.RLock()
+	defer r.lastMu.RUnlock()
+
+	pair, ok := r.last[assetId]
+	return pair, ok
+}
@@ -0,0 +1,146 @@
+package wsapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	log "github.com/xlab/suplog"
+)
+
+const serverWriteTimeout = 10 * time.Second
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// Server is an optional inbound HTTP/WS endpoint that lets downstream
+// consumers subscribe to the oracletypes.AssetPair stream an oracle
+// process is already pulling, using the same request/response envelope
+// Client speaks on the outbound side.
+type Server struct {
+	registry *Registry
+	logger   log.Logger
+}
+
+func NewServer(registry *Registry) *Server {
+	return &Server{
+		registry: registry,
+		logger:   log.WithField("svc", "oracle_wsapi"),
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.logger.WithError(err).Warningln("failed to upgrade wsapi connection")
+		return
+	}
+	defer conn.Close()
+
+	sub := newSubscriber()
+	s.registry.add(sub)
+	defer s.registry.remove(sub)
+
+	done := make(chan struct{})
+	go s.writePump(conn, sub, done)
+	s.readPump(conn, sub)
+	close(done)
+}
+
+func (s *Server) writePump(conn *websocket.Conn, sub *subscriber, done chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case frame := <-sub.send:
+			conn.SetWriteDeadline(time.Now().Add(serverWriteTimeout))
+			if err := conn.WriteMessage(websocket.TextMessage, frame); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) readPump(conn *websocket.Conn, sub *subscriber) {
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req websocketReq
+		if err := json.Unmarshal(message, &req); err != nil {
+			continue
+		}
+
+		s.handle(sub, req)
+	}
+}
+
+func (s *Server) handle(sub *subscriber, req websocketReq) {
+	switch req.Method {
+	case MethodSubscribeTicker:
+		var params tickerParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			s.reply(sub, req.ID, nil, err)
+			return
+		}
+		sub.subscribe(params.AssetId)
+		s.reply(sub, req.ID, json.RawMessage(`{"subscribed":true}`), nil)
+
+	case MethodUnsubscribeTicker:
+		var params tickerParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			s.reply(sub, req.ID, nil, err)
+			return
+		}
+		sub.unsubscribe(params.AssetId)
+		s.reply(sub, req.ID, json.RawMessage(`{"unsubscribed":true}`), nil)
+
+	case MethodSubscribeAllAssets:
+		sub.subscribeAll()
+		s.reply(sub, req.ID, json.RawMessage(`{"subscribed":true}`), nil)
+
+	case MethodGetLastPrice:
+		var params tickerParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			s.reply(sub, req.ID, nil, err)
+			return
+		}
+		pair, ok := s.registry.LastPrice(params.AssetId)
+		if !ok {
+			s.reply(sub, req.ID, nil, errNoLastPrice)
+			return
+		}
+		payload, err := json.Marshal(pair)
+		if err != nil {
+			s.reply(sub, req.ID, nil, err)
+			return
+		}
+		s.reply(sub, req.ID, payload, nil)
+
+	default:
+		s.reply(sub, req.ID, nil, errUnknownMethod)
+	}
+}
+
+func (s *Server) reply(sub *subscriber, id string, data json.RawMessage, err error) {
+	res := websocketRes{ID: id, Data: data}
+	if err != nil {
+		res.Error = err.Error()
+	}
+
+	frame, mErr := json.Marshal(res)
+	if mErr != nil {
+		return
+	}
+
+	select {
+	case sub.send <- frame:
+	default:
+	}
+}
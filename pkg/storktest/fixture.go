@@ -0,0 +1,79 @@
+// Package storktest provides a deterministic replay/conformance harness
+// for Stork price feeds: Record captures raw Stork WebSocket frames to a
+// versioned JSONL fixture, NewReplayServer replays them through an
+// in-process httptest WebSocket server, and AssertGoldenAssetPair checks
+// the resulting oracletypes.AssetPair against a golden file byte-for-byte.
+// See oracle/stork_replay_test.go for the tests that drive this harness
+// over the real verification pipeline.
+package storktest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// fixtureVersion is bumped whenever the Frame format changes in a way
+// that isn't backward compatible, so LoadFixture can refuse to load a
+// fixture it no longer knows how to interpret.
+const fixtureVersion = 1
+
+// Frame is one recorded Stork WebSocket text frame.
+type Frame struct {
+	Version int             `json:"version"`
+	Raw     json.RawMessage `json:"raw"`
+}
+
+// LoadFixture reads every frame from a JSONL fixture file.
+func LoadFixture(path string) ([]Frame, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var frames []Frame
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var frame Frame
+		if err := json.Unmarshal(line, &frame); err != nil {
+			return nil, err
+		}
+		if frame.Version != fixtureVersion {
+			return nil, fmt.Errorf("unsupported fixture version %d (want %d)", frame.Version, fixtureVersion)
+		}
+
+		frames = append(frames, frame)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return frames, nil
+}
+
+// SaveFixture writes frames to path as JSONL, one frame per line.
+func SaveFixture(path string, frames []Frame) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, frame := range frames {
+		if err := enc.Encode(frame); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
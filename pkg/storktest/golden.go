@@ -0,0 +1,76 @@
+package storktest
+
+import (
+	"encoding/json"
+	"os"
+
+	oracletypes "github.com/InjectiveLabs/sdk-go/chain/oracle/types"
+	"github.com/pkg/errors"
+)
+
+// updateGoldenEnvVar, when set to a non-empty value, makes
+// AssertGoldenAssetPair write got to goldenPath instead of comparing
+// against it — the usual Go idiom for (re)seeding golden files rather
+// than hand-authoring their byte content.
+const updateGoldenEnvVar = "STORKTEST_UPDATE_GOLDEN"
+
+// TestingT is the minimal subset of *testing.T golden-file assertions
+// need, so this package doesn't force a "testing" import on non-test
+// callers such as Record.
+type TestingT interface {
+	Helper()
+	Fatalf(format string, args ...interface{})
+}
+
+// AssertGoldenAssetPair fails t unless got marshals to exactly the bytes
+// in goldenPath, including every SignedPriceOfAssetPair's Signature,
+// PublisherKey, Timestamp, and Price — so a regression in
+// ConvertSignedPrice, CombineSignatureToString, or a future
+// signature-format change shows up as a byte-level diff.
+func AssertGoldenAssetPair(t TestingT, got oracletypes.AssetPair, goldenPath string) {
+	t.Helper()
+
+	if os.Getenv(updateGoldenEnvVar) != "" {
+		if err := SaveGoldenAssetPair(got, goldenPath); err != nil {
+			t.Fatalf("failed to update golden file %s: %v", goldenPath, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("failed to read golden file %s: %v", goldenPath, err)
+		return
+	}
+
+	gotBytes, err := marshalGolden(got)
+	if err != nil {
+		t.Fatalf("failed to marshal asset pair: %v", err)
+		return
+	}
+
+	if string(gotBytes) != string(want) {
+		t.Fatalf("asset pair for %s does not match golden file %s:\n got:  %s\n want: %s", got.AssetId, goldenPath, gotBytes, want)
+	}
+}
+
+// SaveGoldenAssetPair writes pair to goldenPath in the canonical form
+// AssertGoldenAssetPair compares against, for authoring or intentionally
+// updating a golden file.
+func SaveGoldenAssetPair(pair oracletypes.AssetPair, goldenPath string) error {
+	data, err := marshalGolden(pair)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(goldenPath, data, 0o644)
+}
+
+func marshalGolden(pair oracletypes.AssetPair) ([]byte, error) {
+	data, err := json.MarshalIndent(pair, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal asset pair")
+	}
+
+	return append(data, '\n'), nil
+}
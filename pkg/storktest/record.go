@@ -0,0 +1,65 @@
+package storktest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+)
+
+// RecordOptions configures a --record session against a live Stork
+// endpoint.
+type RecordOptions struct {
+	Url         string
+	Header      string
+	Message     string
+	FrameCount  int
+	FixturePath string
+}
+
+// Record dials a live Stork endpoint, subscribes with opts.Message, and
+// writes the next opts.FrameCount frames verbatim to opts.FixturePath.
+// It is wired up behind the storktest-record CLI (see
+// cmd/storktest-record/main.go), never run as part of normal test
+// execution.
+func Record(opts RecordOptions) error {
+	u, err := url.Parse(opts.Url)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse url")
+	}
+
+	header := http.Header{}
+	if opts.Header != "" {
+		header.Add("Authorization", "Basic "+opts.Header)
+	}
+
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: 45 * time.Second,
+	}
+
+	conn, _, err := dialer.Dial(u.String(), header)
+	if err != nil {
+		return errors.Wrap(err, "failed to dial stork ws endpoint")
+	}
+	defer conn.Close()
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(opts.Message)); err != nil {
+		return errors.Wrap(err, "failed to send subscribe message")
+	}
+
+	frames := make([]Frame, 0, opts.FrameCount)
+	for len(frames) < opts.FrameCount {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return errors.Wrap(err, "failed to read frame")
+		}
+
+		frames = append(frames, Frame{Version: fixtureVersion, Raw: json.RawMessage(message)})
+	}
+
+	return SaveFixture(opts.FixturePath, frames)
+}
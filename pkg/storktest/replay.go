@@ -0,0 +1,52 @@
+package storktest
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gorilla/websocket"
+	log "github.com/xlab/suplog"
+)
+
+var replayUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// NewReplayServer starts an in-process httptest WebSocket server that,
+// for every client connection, reads and discards one subscribe message
+// then streams frames back in recorded order. Callers must Close() the
+// returned server.
+func NewReplayServer(frames []Frame) *httptest.Server {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := replayUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+
+		for _, frame := range frames {
+			if err := conn.WriteMessage(websocket.TextMessage, frame.Raw); err != nil {
+				log.WithError(err).Warningln("storktest: failed to replay frame")
+				return
+			}
+		}
+	})
+
+	return httptest.NewServer(handler)
+}
+
+// WSURL rewrites an httptest server's http(s):// URL to its ws(s)://
+// equivalent, since that's what websocket.Dialer expects.
+func WSURL(server *httptest.Server) string {
+	url := server.URL
+	if len(url) >= 5 && url[:5] == "https" {
+		return "wss" + url[5:]
+	}
+	return "ws" + url[4:]
+}